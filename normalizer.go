@@ -1,6 +1,7 @@
 package normalizer
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -14,6 +15,8 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
+	"unicode/utf8"
 )
 
 const (
@@ -40,6 +43,13 @@ const (
 	//
 	// 原因是虽然 username 单词中包含 name 字符，但是 name 和 username 不是同一个单词，所以会匹配失败。
 	FuzzyMatch
+	// RegexMatch 正则匹配
+	//
+	// Labels 中的每一项都被当作一个正则表达式（在 SetPatterns 时编译并缓存在 NormalizePattern 上），
+	// 解析出的标签文本需要完整匹配该正则表达式才算匹配成功。
+	//
+	// 正则表达式中的命名分组（如 (?P<unit>kg|g)）会作为 `<ValueKey>_<分组名>` 的兄弟字段写入 Items。
+	RegexMatch
 )
 
 var (
@@ -47,32 +57,98 @@ var (
 	spaceCharacterReplacer = strings.NewReplacer("　", " ") // 全角空格替换
 )
 
+// RequiredCondition 的 Operator 取值
+const (
+	RequiredConditionEqual       = "eq"     // 等于
+	RequiredConditionNotEqual    = "ne"     // 不等于
+	RequiredConditionIn          = "in"     // 包含在 Value（数组）中
+	RequiredConditionGreaterThan = "gt"     // 大于
+	RequiredConditionLessThan    = "lt"     // 小于
+	RequiredConditionExists      = "exists" // 存在有效值
+)
+
+// ArrayConfig.Mode 取值
+const (
+	ArraySplitMode  = "SPLIT"  // 使用 ValueTransform.Separators 对值进行切分（默认行为）
+	ArrayJSONMode   = "JSON"   // 值本身是一段 JSON 数组字符串，解析后作为数组
+	ArrayLinesMode  = "LINES"  // 标签之后的每一个续行作为数组的一个元素，而不是换行拼接为一个字符串
+	ArrayRepeatMode = "REPEAT" // 同一个标签允许在原文中多次出现，每次出现贡献数组的一个元素
+)
+
+// TransformContext 传递给 Transformer 的上下文信息
+type TransformContext struct {
+	ValueKey string // 当前转换值所属的 ValueKey
+}
+
+// Transformer 值转换函数，接收原始字符串与上下文，返回转换后的字符串
+type Transformer func(raw string, ctx TransformContext) (string, error)
+
 type ValueTransform struct {
-	MatchMethod int               `json:"match_method"` // 匹配方式（0: 精准匹配、1: 模糊匹配）
-	Replaces    map[string]string `json:"replaces"`     // 需要替换的字符串
-	Separators  []string          `json:"separators"`   // 值分隔符（返回为数组的时候可用）
+	MatchMethod  int               `json:"match_method"` // 匹配方式（0: 精准匹配、1: 模糊匹配）
+	Replaces     map[string]string `json:"replaces"`     // 需要替换的字符串
+	Separators   []string          `json:"separators"`   // 值分隔符（返回为数组的时候可用）
+	Transformers []string          `json:"transformers"` // 按声明顺序执行的转换器名称管道，在 Replaces 替换之后、值类型转换之前运行。
+	// 内置转换器：lower、upper、trim、strip_units、chinese_num_to_arabic、
+	// regex_replace:<正则>|<替换文本>、parse_date:<源布局>（转换为 RFC3339）、map:<name>（通过 Normalizer.RegisterValueMap 注册的字典）。
+	// 也可通过 Normalizer.RegisterTransformer 注册自定义名称。
+}
+
+// RequiredCondition 引用另一个 ValueKey 的值，与 Value 按 Operator 进行比较
+type RequiredCondition struct {
+	ValueKey string      `json:"value_key"` // 被引用的 ValueKey
+	Operator string      `json:"operator"`  // 比较方式（eq、ne、in、gt、lt、exists）
+	Value    interface{} `json:"value"`     // 参与比较的值
+}
+
+// ArrayConfig 描述 arrayValueType 的取值来源与单个元素的处理方式
+type ArrayConfig struct {
+	Mode     string `json:"mode"`      // SPLIT（默认）、JSON、LINES、REPEAT
+	ItemType string `json:"item_type"` // 元素类型：string（默认）、int、float、bool，用于对每个元素单独做类型转换
+	Unique   bool   `json:"unique"`    // 是否对数组元素去重（按去重后首次出现的顺序保留）
+}
+
+// Constraints 值约束设置
+type Constraints struct {
+	Min                    *float64              `json:"min"`                      // 最小值（int、float 有效）
+	Max                    *float64              `json:"max"`                      // 最大值（int、float 有效）
+	MinLen                 *int                  `json:"min_len"`                  // 最小长度（string 有效）
+	MaxLen                 *int                  `json:"max_len"`                  // 最大长度（string 有效）
+	Regex                  string                `json:"regex"`                    // 正则表达式（string 有效）
+	Enum                   []interface{}         `json:"enum"`                     // 枚举值
+	MinItems               *int                  `json:"min_items"`                // 最少元素个数（array 有效）
+	MaxItems               *int                  `json:"max_items"`                // 最多元素个数（array 有效）
+	RequiredConditionGroup [][]RequiredCondition `json:"required_condition_group"` // 必填条件分组，组内为 AND，组间为 OR；为空表示该字段非必填
 }
 
 type NormalizePattern struct {
-	used           bool           // 是否使用过（用于内部判断是否需要使用该规则）
-	Labels         []string       `json:"labels"`          // 标签关键词（可以有多个）
-	MatchMethod    int            `json:"match_method"`    // 匹配方式（0: 精准匹配、1: 模糊匹配）
-	Separator      string         `json:"separator"`       // 文本段分隔符
-	ValueKey       string         `json:"value_key"`       // 解析后返回数据中值使用的 key
-	ValueTransform ValueTransform `json:"value_transform"` // 值转化设置
-	ValueType      string         `json:"value_type"`      // 值类型
-	DefaultValue   interface{}    `json:"default_value"`   // 默认值
+	used             bool             // 是否使用过（用于内部判断是否需要使用该规则）
+	labelRegexes     []*regexp.Regexp // MatchMethod 为 RegexMatch 时，Labels 编译后缓存的正则表达式
+	separatorRegex   *regexp.Regexp   // SeparatorIsRegex 为 true 时，Separator 编译后缓存的正则表达式
+	Labels           []string         `json:"labels"`             // 标签关键词（可以有多个）
+	MatchMethod      int              `json:"match_method"`       // 匹配方式（0: 精准匹配、1: 模糊匹配、2: 正则匹配）
+	Separator        string           `json:"separator"`          // 文本段分隔符
+	SeparatorIsRegex bool             `json:"separator_is_regex"` // Separator 是否按正则表达式处理
+	ValueKey         string           `json:"value_key"`          // 解析后返回数据中值使用的 key，支持 gjson/sjson 风格的嵌套路径（如 author.name、contacts.0.phone、tags.#）
+	ValueTransform   ValueTransform   `json:"value_transform"`    // 值转化设置
+	ValueType        string           `json:"value_type"`         // 值类型
+	DefaultValue     interface{}      `json:"default_value"`      // 默认值
+	Constraints      Constraints      `json:"constraints"`        // 值约束设置
+	Transformers     []Transformer    `json:"-"`                  // 程序化注册的转换器管道，在 ValueTransform.Transformers 之后按声明顺序执行
+	ArrayConfig      ArrayConfig      `json:"array_config"`       // ValueType 为 array 时的取值模式设置
 }
 
 type Normalizer struct {
-	labels       map[string]struct{}    // 文本中所有的标签
-	separator    string                 // 文本行分隔符
-	strictMode   bool                   // 严格模式
-	validate     bool                   // 设置是否有效
-	Errors       []string               // 错误信息
-	OriginalText string                 // 原始的文本
-	Patterns     []NormalizePattern     // 解析规则
-	Items        map[string]interface{} // 解析后返回的值
+	labels       map[string]struct{}          // 文本中所有的标签
+	separator    string                       // 文本行分隔符
+	strictMode   bool                         // 严格模式
+	validate     bool                         // 设置是否有效
+	transformers map[string]Transformer       // 通过 RegisterTransformer 注册的自定义转换器
+	valueMaps    map[string]map[string]string // 通过 RegisterValueMap 注册的 map:<name> 转换器所用的字典
+	parsedKeys   map[string]struct{}          // 本次 Parse() 中实际从原文解析出值的 ValueKey 集合
+	Errors       []string                     // 错误信息
+	OriginalText string                       // 原始的文本
+	Patterns     []NormalizePattern           // 解析规则
+	Items        map[string]interface{}       // 解析后返回的值
 }
 
 func NewNormalizer() *Normalizer {
@@ -136,11 +212,284 @@ func (n *Normalizer) SetLabels(labels []string) *Normalizer {
 	return n
 }
 
+// RegisterTransformer 注册一个可在 ValueTransform.Transformers 中按名称引用的自定义转换器
+func (n *Normalizer) RegisterTransformer(name string, fn Transformer) *Normalizer {
+	if n.transformers == nil {
+		n.transformers = map[string]Transformer{}
+	}
+	n.transformers[name] = fn
+	return n
+}
+
+// RegisterValueMap 注册一个供 map:<name> 内置转换器使用的字典
+func (n *Normalizer) RegisterValueMap(name string, values map[string]string) *Normalizer {
+	if n.valueMaps == nil {
+		n.valueMaps = map[string]map[string]string{}
+	}
+	n.valueMaps[name] = values
+	return n
+}
+
+var (
+	rxTrailingUnit = regexp.MustCompile(`[^\d.+-]+$`)
+	chineseDigits  = map[rune]int{'零': 0, '一': 1, '二': 2, '两': 2, '三': 3, '四': 4, '五': 5, '六': 6, '七': 7, '八': 8, '九': 9}
+	chineseUnits   = map[rune]int{'十': 10, '百': 100, '千': 1000, '万': 10000}
+)
+
+// chineseNumToArabic 将常见的中文数字（零一二三四五六七八九十百千万）转换为阿拉伯数字文本
+//
+// 遇到无法识别的字符时原样返回，避免破坏原始文本。
+func chineseNumToArabic(s string) string {
+	hasDigit := false
+	total, section, number := 0, 0, 0
+	for _, r := range s {
+		if d, ok := chineseDigits[r]; ok {
+			number = d
+			hasDigit = true
+			continue
+		}
+		if u, ok := chineseUnits[r]; ok {
+			hasDigit = true
+			if number == 0 {
+				number = 1
+			}
+			if u == 10000 {
+				total += (section + number) * u
+				section = 0
+			} else {
+				section += number * u
+			}
+			number = 0
+			continue
+		}
+		return s
+	}
+	if !hasDigit {
+		return s
+	}
+	return strconv.Itoa(total + section + number)
+}
+
+// builtinTransformers 内置转换器，名称不带参数
+func builtinTransformers() map[string]Transformer {
+	return map[string]Transformer{
+		"lower": func(raw string, _ TransformContext) (string, error) {
+			return strings.ToLower(raw), nil
+		},
+		"upper": func(raw string, _ TransformContext) (string, error) {
+			return strings.ToUpper(raw), nil
+		},
+		"trim": func(raw string, _ TransformContext) (string, error) {
+			return strings.TrimSpace(raw), nil
+		},
+		"strip_units": func(raw string, _ TransformContext) (string, error) {
+			return rxTrailingUnit.ReplaceAllString(strings.TrimSpace(raw), ""), nil
+		},
+		"chinese_num_to_arabic": func(raw string, _ TransformContext) (string, error) {
+			return chineseNumToArabic(raw), nil
+		},
+	}
+}
+
+// resolveTransformer 按名称解析一个转换器，依次尝试带参数的内置转换器（regex_replace、parse_date、map）、
+// 不带参数的内置转换器，最后回退到通过 RegisterTransformer 注册的自定义转换器
+func (n *Normalizer) resolveTransformer(name string) (Transformer, bool) {
+	base, param, hasParam := strings.Cut(name, ":")
+	switch base {
+	case "regex_replace":
+		if !hasParam {
+			return nil, false
+		}
+		// 正则表达式本身可能包含 "|"（分支语法，如 (foo|bar)），因此按最后一个 "|" 切分，
+		// 而不是第一个，避免把正则表达式的分支语法误当作参数分隔符切断。
+		idx := strings.LastIndex(param, "|")
+		if idx == -1 {
+			return nil, false
+		}
+		re, err := regexp.Compile(param[:idx])
+		if err != nil {
+			return nil, false
+		}
+		replacement := param[idx+1:]
+		return func(raw string, _ TransformContext) (string, error) {
+			return re.ReplaceAllString(raw, replacement), nil
+		}, true
+	case "parse_date":
+		if !hasParam {
+			return nil, false
+		}
+		layout := param
+		return func(raw string, _ TransformContext) (string, error) {
+			t, err := time.Parse(layout, strings.TrimSpace(raw))
+			if err != nil {
+				return raw, err
+			}
+			return t.Format(time.RFC3339), nil
+		}, true
+	case "map":
+		if !hasParam {
+			return nil, false
+		}
+		mapName := param
+		return func(raw string, _ TransformContext) (string, error) {
+			if values, ok := n.valueMaps[mapName]; ok {
+				if v, ok := values[raw]; ok {
+					return v, nil
+				}
+			}
+			return raw, nil
+		}, true
+	default:
+		if fn, ok := builtinTransformers()[name]; ok {
+			return fn, true
+		}
+		if fn, ok := n.transformers[name]; ok {
+			return fn, true
+		}
+		return nil, false
+	}
+}
+
+// coerceArrayItems 按 itemType（string/int/float/bool，默认为 string）对数组的每个元素单独做类型转换
+func coerceArrayItems(items []interface{}, itemType string) []interface{} {
+	if itemType == "" || itemType == stringValueType {
+		return items
+	}
+	coerced := make([]interface{}, len(items))
+	for i, item := range items {
+		switch itemType {
+		case intValueType:
+			coerced[i] = cast.ToInt64(item)
+		case floatValueType:
+			coerced[i] = cast.ToFloat64(item)
+		case booleanValueType, "bool":
+			coerced[i] = cast.ToBool(item)
+		default:
+			coerced[i] = cast.ToString(item)
+		}
+	}
+	return coerced
+}
+
+// dedupeInterfaceSlice 按元素文本表示去重，保留首次出现的顺序
+func dedupeInterfaceSlice(values []interface{}) []interface{} {
+	seen := make(map[string]struct{}, len(values))
+	result := make([]interface{}, 0, len(values))
+	for _, v := range values {
+		key := fmt.Sprintf("%v", v)
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		result = append(result, v)
+	}
+	return result
+}
+
+// setPathValue 按照 gjson/sjson 风格的路径语义将 value 写入 current 中并返回写入后的容器
+//
+// 路径以 "." 分隔，数字片段表示数组下标（不足长度时以 nil 补齐），"#" 表示向数组追加一个新元素，
+// 其余片段按 map 的 key 处理。current 为 nil 或类型不匹配时会按需创建 map 或数组。
+func setPathValue(current interface{}, segments []string, value interface{}) interface{} {
+	if len(segments) == 0 {
+		return value
+	}
+	seg := segments[0]
+	rest := segments[1:]
+	if seg == "#" {
+		arr, _ := current.([]interface{})
+		return append(arr, setPathValue(nil, rest, value))
+	}
+	if idx, err := strconv.Atoi(seg); err == nil {
+		arr, _ := current.([]interface{})
+		for len(arr) <= idx {
+			arr = append(arr, nil)
+		}
+		arr[idx] = setPathValue(arr[idx], rest, value)
+		return arr
+	}
+	m, ok := current.(map[string]interface{})
+	if !ok || m == nil {
+		m = map[string]interface{}{}
+	}
+	m[seg] = setPathValue(m[seg], rest, value)
+	return m
+}
+
+// setByPath 将 value 写入 root 中 key 所指向的路径（key 支持 "." 分隔的嵌套路径）
+func setByPath(root map[string]interface{}, key string, value interface{}) {
+	setPathValue(root, strings.Split(key, "."), value)
+}
+
+// seedPathValue 与 setPathValue 类似，但用于在解析开始前按路径预先创建容器并写入默认值。
+//
+// 与 setPathValue 的区别在于遇到 "#" 片段时不会追加占位元素，而是仅确保该层级是一个数组容器；
+// 因为 "#" 代表“追加一个新元素”，在尚未解析出任何实际值之前就追加会产生一个多余的默认值占位元素
+// （例如 ValueKey 为 "tags.#" 时，会导致默认值被当成第一个元素写入数组）。
+func seedPathValue(current interface{}, segments []string, value interface{}) interface{} {
+	if len(segments) == 0 {
+		return value
+	}
+	seg := segments[0]
+	rest := segments[1:]
+	if seg == "#" {
+		arr, _ := current.([]interface{})
+		if arr == nil {
+			arr = []interface{}{}
+		}
+		return arr
+	}
+	if idx, err := strconv.Atoi(seg); err == nil {
+		arr, _ := current.([]interface{})
+		for len(arr) <= idx {
+			arr = append(arr, nil)
+		}
+		arr[idx] = seedPathValue(arr[idx], rest, value)
+		return arr
+	}
+	m, ok := current.(map[string]interface{})
+	if !ok || m == nil {
+		m = map[string]interface{}{}
+	}
+	m[seg] = seedPathValue(m[seg], rest, value)
+	return m
+}
+
+// seedByPath 在 root 中 key 所指向的路径上写入默认值，"#" 结尾的路径只确保数组容器存在而不追加元素
+func seedByPath(root map[string]interface{}, key string, value interface{}) {
+	seedPathValue(root, strings.Split(key, "."), value)
+}
+
+// getByPath 读取 root 中 key 所指向路径的值，第二个返回值表示该路径是否存在
+func getByPath(root map[string]interface{}, key string) (interface{}, bool) {
+	var current interface{} = root
+	for _, seg := range strings.Split(key, ".") {
+		switch c := current.(type) {
+		case map[string]interface{}:
+			v, ok := c[seg]
+			if !ok {
+				return nil, false
+			}
+			current = v
+		case []interface{}:
+			idx, err := strconv.Atoi(seg)
+			if err != nil || idx < 0 || idx >= len(c) {
+				return nil, false
+			}
+			current = c[idx]
+		default:
+			return nil, false
+		}
+	}
+	return current, true
+}
+
 // SetPatterns 设置匹配规则
 func (n *Normalizer) SetPatterns(patterns []NormalizePattern) *Normalizer {
 	n.validate = false
 	n.Patterns = patterns
 	items := make(map[string]interface{}, len(patterns))
+	errs := make([]string, 0)
 	for i, pattern := range n.Patterns {
 		// 规则设置规则
 		if pattern.Separator == "" {
@@ -151,14 +500,41 @@ func (n *Normalizer) SetPatterns(patterns []NormalizePattern) *Normalizer {
 				delete(n.Patterns[i].ValueTransform.Replaces, k)
 			}
 		}
-		for j, label := range pattern.Labels {
-			label = clean(label, n.strictMode)
-			n.Patterns[i].Labels[j] = label
-			if label == "" {
-				continue
+		if pattern.MatchMethod == RegexMatch {
+			// RegexMatch 模式下 Labels 本身即为正则表达式，不做大小写/空格清洗，
+			// 在此编译为锚定的正则表达式并缓存，避免每行都重新编译。
+			// Labels 来自外部 JSON 配置，正则表达式可能非法，编译失败时记录错误而不是让
+			// 整个进程 panic，失败的一项保留为 nil，使用处会先判空再匹配。
+			regexes := make([]*regexp.Regexp, len(pattern.Labels))
+			for j, keyword := range pattern.Labels {
+				re, rerr := regexp.Compile(`^(?:` + keyword + `)$`)
+				if rerr != nil {
+					errs = append(errs, fmt.Sprintf("解析规则第 %d 项标签关键词 %s 不是合法的正则表达式：%s", i+1, keyword, rerr.Error()))
+					continue
+				}
+				regexes[j] = re
 			}
-			if _, ok := n.labels[label]; !ok {
-				n.labels[label] = struct{}{}
+			n.Patterns[i].labelRegexes = regexes
+		} else {
+			for j, label := range pattern.Labels {
+				label = clean(label, n.strictMode)
+				n.Patterns[i].Labels[j] = label
+				if label == "" {
+					continue
+				}
+				if _, ok := n.labels[label]; !ok {
+					n.labels[label] = struct{}{}
+				}
+			}
+		}
+		if pattern.SeparatorIsRegex {
+			// Separator 同样来自外部 JSON 配置，理由同上：编译失败时记录错误而不是 panic，
+			// separatorRegex 保持为 nil，使用处已按 nil 判断回退为字面量分隔符。
+			re, rerr := regexp.Compile(n.Patterns[i].Separator)
+			if rerr != nil {
+				errs = append(errs, fmt.Sprintf("解析规则第 %d 项分隔符 %s 不是合法的正则表达式：%s", i+1, n.Patterns[i].Separator, rerr.Error()))
+			} else {
+				n.Patterns[i].separatorRegex = re
 			}
 		}
 		// 防止默认值设置错误
@@ -180,10 +556,10 @@ func (n *Normalizer) SetPatterns(patterns []NormalizePattern) *Normalizer {
 		default:
 			defaultValue = cast.ToString(defaultValue)
 		}
-		items[pattern.ValueKey] = defaultValue
+		seedByPath(items, pattern.ValueKey, defaultValue)
 	}
 	n.Items = items
-	n.Errors = []string{}
+	n.Errors = errs
 	return n
 }
 
@@ -203,6 +579,7 @@ func (n *Normalizer) Parse() *Normalizer {
 		// Reset
 		n.Patterns[i].used = false
 	}
+	n.parsedKeys = make(map[string]struct{})
 
 	type labelValue struct {
 		key            string
@@ -210,6 +587,9 @@ func (n *Normalizer) Parse() *Normalizer {
 		value          string
 		valueType      string
 		valueTransform ValueTransform
+		transformers   []Transformer     // pattern.Transformers 原样传递，在 valueTransform.Transformers 之后执行
+		arrayConfig    ArrayConfig       // ValueType 为 array 时的取值模式设置
+		extra          map[string]string // RegexMatch 模式下命名分组产生的兄弟字段（<ValueKey>_<分组名> -> 值）
 	}
 
 	lines := make([]labelValue, 0)
@@ -228,6 +608,41 @@ func (n *Normalizer) Parse() *Normalizer {
 				break
 			}
 		}
+		if isPureText {
+			// n.labels 仅记录字面量标签，RegexMatch 模式的标签以正则表达式注册在各 pattern 的
+			// labelRegexes 上，因此这里需要额外尝试用该行的分隔符切出候选标签，逐一匹配，
+			// 避免把实际匹配到另一个 RegexMatch 字段的行误判为上一个字段的续行文本。
+			for _, pattern := range n.Patterns {
+				if pattern.MatchMethod != RegexMatch || len(pattern.labelRegexes) == 0 {
+					continue
+				}
+				var separatorIndex, separatorLen int
+				if pattern.SeparatorIsRegex && pattern.separatorRegex != nil {
+					loc := pattern.separatorRegex.FindStringIndex(lineText)
+					if loc == nil {
+						continue
+					}
+					separatorIndex, separatorLen = loc[0], loc[1]-loc[0]
+				} else {
+					separatorIndex = strings.Index(lineText, pattern.Separator)
+					if separatorIndex == -1 {
+						continue
+					}
+					separatorLen = len(pattern.Separator)
+				}
+				_ = separatorLen
+				candidateLabel := clean(lineText[0:separatorIndex], n.strictMode)
+				for _, re := range pattern.labelRegexes {
+					if re != nil && re.MatchString(candidateLabel) {
+						isPureText = false
+						break
+					}
+				}
+				if !isPureText {
+					break
+				}
+			}
+		}
 		if isPureText && appendText {
 			m := len(lines)
 			if m == 0 {
@@ -240,6 +655,8 @@ func (n *Normalizer) Parse() *Normalizer {
 				value:          lineText,
 				valueType:      lines[m].valueType,
 				valueTransform: lines[m].valueTransform,
+				transformers:   lines[m].transformers,
+				arrayConfig:    lines[m].arrayConfig,
 			})
 		}
 		matched := false
@@ -248,34 +665,77 @@ func (n *Normalizer) Parse() *Normalizer {
 			if pattern.used {
 				continue
 			}
-			separatorIndex := strings.Index(lineText, pattern.Separator)
-			if separatorIndex == -1 {
-				continue
+			var separatorIndex, separatorLen int
+			if pattern.SeparatorIsRegex && pattern.separatorRegex != nil {
+				loc := pattern.separatorRegex.FindStringIndex(lineText)
+				if loc == nil {
+					continue
+				}
+				separatorIndex, separatorLen = loc[0], loc[1]-loc[0]
+			} else {
+				separatorIndex = strings.Index(lineText, pattern.Separator)
+				if separatorIndex == -1 {
+					continue
+				}
+				separatorLen = len(pattern.Separator)
 			}
 			label := clean(lineText[0:separatorIndex], n.strictMode)
-			for _, keyword := range pattern.Labels {
-				if pattern.MatchMethod == FuzzyMatch {
+			for j, keyword := range pattern.Labels {
+				var namedGroups map[string]string
+				switch pattern.MatchMethod {
+				case FuzzyMatch:
 					// 匹配单词（忽略大小写）
 					reg := regexp.MustCompile(`(?i)(^|([\s\t\n]+))(` + keyword + `)($|([\s\t\n]+))`)
 					matched = reg.MatchString(label)
-				} else {
+				case RegexMatch:
+					re := pattern.labelRegexes[j]
+					if re == nil {
+						continue
+					}
+					matched = re.MatchString(label)
+					if matched {
+						if groups := re.FindStringSubmatch(label); groups != nil {
+							for gi, name := range re.SubexpNames() {
+								if gi == 0 || name == "" {
+									continue
+								}
+								if namedGroups == nil {
+									namedGroups = make(map[string]string)
+								}
+								namedGroups[name] = groups[gi]
+							}
+						}
+					}
+				default:
 					matched = label == keyword
 				}
 				if matched {
 					lv.key = pattern.ValueKey
 					lv.label = label
-					lv.value = strings.TrimSpace(lineText[separatorIndex+1:])
+					lv.value = strings.TrimSpace(lineText[separatorIndex+separatorLen:])
 					lv.valueType = pattern.ValueType
 					lv.valueTransform = ValueTransform{
-						MatchMethod: pattern.ValueTransform.MatchMethod,
-						Replaces:    pattern.ValueTransform.Replaces,
-						Separators:  pattern.ValueTransform.Separators,
+						MatchMethod:  pattern.ValueTransform.MatchMethod,
+						Replaces:     pattern.ValueTransform.Replaces,
+						Separators:   pattern.ValueTransform.Separators,
+						Transformers: pattern.ValueTransform.Transformers,
+					}
+					lv.transformers = pattern.Transformers
+					lv.arrayConfig = pattern.ArrayConfig
+					if namedGroups != nil {
+						lv.extra = make(map[string]string, len(namedGroups))
+						for name, v := range namedGroups {
+							lv.extra[pattern.ValueKey+"_"+name] = v
+						}
 					}
 					break
 				}
 			}
 			if matched {
-				n.Patterns[i].used = true
+				// REPEAT 模式下同一个标签允许重复出现，因此不标记为已使用，以便后续相同标签的行继续匹配
+				if !(pattern.ValueType == arrayValueType && pattern.ArrayConfig.Mode == ArrayRepeatMode) {
+					n.Patterns[i].used = true
+				}
 				break
 			}
 		}
@@ -321,6 +781,29 @@ func (n *Normalizer) Parse() *Normalizer {
 
 			rawValue = strings.TrimSpace(rawValue)
 		}
+		// 值转换器管道：先执行按名称声明的 Transformers（内置或 RegisterTransformer 注册的自定义转换器），
+		// 再执行程序化注册在 pattern.Transformers 上的转换器，均按声明顺序依次执行
+		for _, name := range line.valueTransform.Transformers {
+			fn, ok := n.resolveTransformer(name)
+			if !ok {
+				n.Errors = append(n.Errors, fmt.Sprintf("未注册的转换器：%s", name))
+				continue
+			}
+			transformed, terr := fn(rawValue, TransformContext{ValueKey: line.key})
+			if terr != nil {
+				n.Errors = append(n.Errors, terr.Error())
+				continue
+			}
+			rawValue = transformed
+		}
+		for _, fn := range line.transformers {
+			transformed, terr := fn(rawValue, TransformContext{ValueKey: line.key})
+			if terr != nil {
+				n.Errors = append(n.Errors, terr.Error())
+				continue
+			}
+			rawValue = transformed
+		}
 		var value interface{}
 		switch line.valueType {
 		case booleanValueType:
@@ -342,7 +825,31 @@ func (n *Normalizer) Parse() *Normalizer {
 		case floatValueType:
 			value, err = strconv.ParseFloat(rawValue, 64)
 		case arrayValueType:
-			value = slicex.StringToInterface(stringx.Split(rawValue, line.valueTransform.Separators...))
+			switch line.arrayConfig.Mode {
+			case ArrayJSONMode:
+				var arr []interface{}
+				decoder := json.NewDecoder(strings.NewReader(rawValue))
+				decoder.UseNumber()
+				if jerr := decoder.Decode(&arr); jerr != nil {
+					n.Errors = append(n.Errors, jerr.Error())
+					arr = []interface{}{}
+				}
+				value = arr
+			case ArrayLinesMode:
+				// 每个续行是一个元素；标签所在行本身的文本通常为空（值在续行中），
+				// 为空时不应凭空产生一个空字符串元素，真正的元素由 appendText 续行拼接逻辑补上
+				if rawValue != "" {
+					value = []interface{}{rawValue}
+				} else {
+					value = []interface{}{}
+				}
+			case ArrayRepeatMode:
+				// 每次重复出现的标签贡献一个元素，即使该次出现的值为空也算一次有效出现
+				value = []interface{}{rawValue}
+			default:
+				value = slicex.StringToInterface(stringx.Split(rawValue, line.valueTransform.Separators...))
+			}
+			value = coerceArrayItems(value.([]interface{}), line.arrayConfig.ItemType)
 		default:
 			// Value is string type
 			value = rawValue
@@ -350,7 +857,8 @@ func (n *Normalizer) Parse() *Normalizer {
 		if err != nil {
 			n.Errors = append(n.Errors, err.Error())
 		}
-		if v, ok := n.Items[line.key]; ok {
+		n.parsedKeys[line.key] = struct{}{}
+		if v, ok := getByPath(n.Items, line.key); ok {
 			switch line.valueType {
 			case stringValueType:
 				if v != "" {
@@ -358,20 +866,173 @@ func (n *Normalizer) Parse() *Normalizer {
 				} else {
 					v = value
 				}
-				n.Items[line.key] = v
+				setByPath(n.Items, line.key, v)
 			case arrayValueType:
-				n.Items[line.key] = append(v.([]interface{}), value.([]interface{})...)
+				merged := append(v.([]interface{}), value.([]interface{})...)
+				if line.arrayConfig.Unique {
+					merged = dedupeInterfaceSlice(merged)
+				}
+				setByPath(n.Items, line.key, merged)
 			default:
-				n.Items[line.key] = value
+				setByPath(n.Items, line.key, value)
 			}
 		} else {
-			n.Items[line.key] = value
+			if line.valueType == arrayValueType && line.arrayConfig.Unique {
+				value = dedupeInterfaceSlice(value.([]interface{}))
+			}
+			setByPath(n.Items, line.key, value)
+		}
+		for k, v := range line.extra {
+			setByPath(n.Items, k, v)
 		}
 	}
 
+	n.validateConstraints()
+
 	return n
 }
 
+// conditionSatisfied 判断 cond 所引用的 ValueKey 当前值是否满足 cond 设置的比较条件
+func conditionSatisfied(items map[string]interface{}, cond RequiredCondition) bool {
+	v, ok := getByPath(items, cond.ValueKey)
+	switch cond.Operator {
+	case RequiredConditionExists:
+		return ok && !isZeroLike(v)
+	case RequiredConditionEqual:
+		return ok && fmt.Sprintf("%v", v) == fmt.Sprintf("%v", cond.Value)
+	case RequiredConditionNotEqual:
+		return !ok || fmt.Sprintf("%v", v) != fmt.Sprintf("%v", cond.Value)
+	case RequiredConditionIn:
+		if !ok {
+			return false
+		}
+		values, _ := cond.Value.([]interface{})
+		for _, vv := range values {
+			if fmt.Sprintf("%v", vv) == fmt.Sprintf("%v", v) {
+				return true
+			}
+		}
+		return false
+	case RequiredConditionGreaterThan:
+		return ok && cast.ToFloat64(v) > cast.ToFloat64(cond.Value)
+	case RequiredConditionLessThan:
+		return ok && cast.ToFloat64(v) < cast.ToFloat64(cond.Value)
+	default:
+		return false
+	}
+}
+
+// isZeroLike 判断值是否为对应类型的零值（未被有效解析赋值）
+func isZeroLike(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case bool:
+		return !v
+	case int:
+		return v == 0
+	case int64:
+		return v == 0
+	case float64:
+		return v == 0
+	case []interface{}:
+		return len(v) == 0
+	case map[string]interface{}:
+		return len(v) == 0
+	default:
+		return false
+	}
+}
+
+// validateConstraints 在所有文本行解析完成后，按 Patterns 顺序校验必填条件与值约束，
+// 违反项以描述性文本追加到 n.Errors 中
+func (n *Normalizer) validateConstraints() {
+	for _, pattern := range n.Patterns {
+		c := pattern.Constraints
+		value, _ := getByPath(n.Items, pattern.ValueKey)
+		if len(c.RequiredConditionGroup) > 0 {
+			required := false
+			for _, group := range c.RequiredConditionGroup {
+				allMatched := true
+				for _, cond := range group {
+					if !conditionSatisfied(n.Items, cond) {
+						allMatched = false
+						break
+					}
+				}
+				if allMatched {
+					required = true
+					break
+				}
+			}
+			if required && isZeroLike(value) {
+				n.Errors = append(n.Errors, fmt.Sprintf("%s 为必填项，但未获取到有效值", pattern.ValueKey))
+				continue
+			}
+		}
+		// 只有实际从原文中解析出值的字段才需要校验 Constraints，避免未出现的可选字段的
+		// 零值（默认值）被 Min/MaxLen/Enum 等约束误判为不满足
+		if _, parsed := n.parsedKeys[pattern.ValueKey]; !parsed {
+			continue
+		}
+		n.checkConstraints(pattern, value)
+	}
+}
+
+// checkConstraints 校验单个 pattern 的值是否满足其 Constraints 设置
+func (n *Normalizer) checkConstraints(pattern NormalizePattern, value interface{}) {
+	c := pattern.Constraints
+	switch pattern.ValueType {
+	case intValueType, floatValueType:
+		f := cast.ToFloat64(value)
+		if c.Min != nil && f < *c.Min {
+			n.Errors = append(n.Errors, fmt.Sprintf("%s 的值 %v 小于最小值 %v", pattern.ValueKey, value, *c.Min))
+		}
+		if c.Max != nil && f > *c.Max {
+			n.Errors = append(n.Errors, fmt.Sprintf("%s 的值 %v 大于最大值 %v", pattern.ValueKey, value, *c.Max))
+		}
+	case stringValueType:
+		s := cast.ToString(value)
+		// 使用字符数而非字节数，避免多字节字符（如中文）的长度被按 UTF-8 字节数错误放大
+		sLen := utf8.RuneCountInString(s)
+		if c.MinLen != nil && sLen < *c.MinLen {
+			n.Errors = append(n.Errors, fmt.Sprintf("%s 的长度 %d 小于最小长度 %d", pattern.ValueKey, sLen, *c.MinLen))
+		}
+		if c.MaxLen != nil && sLen > *c.MaxLen {
+			n.Errors = append(n.Errors, fmt.Sprintf("%s 的长度 %d 大于最大长度 %d", pattern.ValueKey, sLen, *c.MaxLen))
+		}
+		if c.Regex != "" {
+			if matched, err := regexp.MatchString(c.Regex, s); err != nil {
+				n.Errors = append(n.Errors, fmt.Sprintf("%s 设置的正则表达式 %s 无效：%s", pattern.ValueKey, c.Regex, err.Error()))
+			} else if !matched {
+				n.Errors = append(n.Errors, fmt.Sprintf("%s 的值 %s 不满足正则表达式 %s", pattern.ValueKey, s, c.Regex))
+			}
+		}
+	case arrayValueType:
+		arr, _ := value.([]interface{})
+		if c.MinItems != nil && len(arr) < *c.MinItems {
+			n.Errors = append(n.Errors, fmt.Sprintf("%s 的元素个数 %d 小于最小个数 %d", pattern.ValueKey, len(arr), *c.MinItems))
+		}
+		if c.MaxItems != nil && len(arr) > *c.MaxItems {
+			n.Errors = append(n.Errors, fmt.Sprintf("%s 的元素个数 %d 大于最大个数 %d", pattern.ValueKey, len(arr), *c.MaxItems))
+		}
+	}
+	if len(c.Enum) > 0 {
+		matched := false
+		for _, e := range c.Enum {
+			if fmt.Sprintf("%v", e) == fmt.Sprintf("%v", value) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			n.Errors = append(n.Errors, fmt.Sprintf("%s 的值 %v 不在允许的枚举范围 %v 内", pattern.ValueKey, value, c.Enum))
+		}
+	}
+}
+
 // Validate 验证设置是否有效
 func (n *Normalizer) Validate() error {
 	if n.validate {
@@ -395,6 +1056,19 @@ func (n *Normalizer) Validate() error {
 		if len(p1.Labels) == 0 {
 			return fmt.Errorf("解析规则第 %d 项未设置标签关键词", i+1)
 		}
+		if p1.ValueType == arrayValueType && p1.ArrayConfig.Mode != "" && !inx.StringIn(p1.ArrayConfig.Mode, ArraySplitMode, ArrayJSONMode, ArrayLinesMode, ArrayRepeatMode) {
+			return fmt.Errorf("解析规则第 %d 项数组模式 %s 设置有误，有效的模式为：%s", i+1, p1.ArrayConfig.Mode, strings.Join([]string{ArraySplitMode, ArrayJSONMode, ArrayLinesMode, ArrayRepeatMode}, ", "))
+		}
+		if p1.MatchMethod == RegexMatch {
+			for j, keyword := range p1.Labels {
+				if j >= len(p1.labelRegexes) || p1.labelRegexes[j] == nil {
+					return fmt.Errorf("解析规则第 %d 项标签关键词 %s 不是合法的正则表达式", i+1, keyword)
+				}
+			}
+		}
+		if p1.SeparatorIsRegex && p1.separatorRegex == nil {
+			return fmt.Errorf("解析规则第 %d 项分隔符 %s 不是合法的正则表达式", i+1, p1.Separator)
+		}
 		for j := i + 1; j < m; j++ {
 			p2 := n.Patterns[j]
 			if strings.EqualFold(p1.ValueKey, p2.ValueKey) {
@@ -419,6 +1093,30 @@ func (n *Normalizer) Ok() bool {
 	return len(n.Errors) == 0
 }
 
+// ParseInto 在 Parse() 之后，将内部 Items 解码到 dst 指向的结构体中
+//
+// 解码使用 json.Number 承载数字，避免整型/浮点精度丢失；dst 的字段通过 json tag 与
+// NormalizePattern.ValueKey 对应。若 n.Errors 中存在解析错误，会合并后一并返回。
+func (n *Normalizer) ParseInto(dst interface{}) error {
+	b, err := json.Marshal(n.Items)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+	if err = decoder.Decode(dst); err != nil {
+		return err
+	}
+	if len(n.Errors) > 0 {
+		errs := make([]error, len(n.Errors))
+		for i, e := range n.Errors {
+			errs[i] = errors.New(e)
+		}
+		return errors.Join(errs...)
+	}
+	return nil
+}
+
 // ToJson 输出 JSON 字符
 func (n *Normalizer) ToJson() string {
 	return jsonx.ToPrettyJson(n.Items)
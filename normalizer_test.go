@@ -72,6 +72,8 @@ func TestNormalizer_Parse(t *testing.T) {
 					items[k] = interfaceValues
 				} else if vv, ok := v.(int64); ok {
 					items[k] = float64(vv)
+				} else if vv, ok := v.(int); ok {
+					items[k] = float64(vv)
 				}
 			}
 			assert.Equal(t, d.Want, items, "%s - %s 项目比对错误：%#v", name, d.Description, normalizer.Errors)
@@ -79,6 +81,84 @@ func TestNormalizer_Parse(t *testing.T) {
 	}
 }
 
+func TestNormalizer_ParseInto(t *testing.T) {
+	type person struct {
+		Name string `json:"name"`
+		Age  int    `json:"age"`
+	}
+	n := NewNormalizer()
+	n.SetOriginalText("name:John\nage:12").
+		SetSeparator("\n").
+		SetLabels([]string{"name", "age"}).
+		SetPatterns([]NormalizePattern{
+			{
+				Labels:       []string{"name"},
+				MatchMethod:  0,
+				Separator:    ":",
+				ValueKey:     "name",
+				ValueType:    "string",
+				DefaultValue: "",
+			},
+			{
+				Labels:       []string{"age"},
+				MatchMethod:  0,
+				Separator:    ":",
+				ValueKey:     "age",
+				ValueType:    "int",
+				DefaultValue: 0,
+			},
+		}).
+		Parse()
+
+	var p person
+	err := n.ParseInto(&p)
+	assert.Equal(t, nil, err, "ParseInto() error: %#v", n.Errors)
+	assert.Equal(t, person{Name: "John", Age: 12}, p)
+}
+
+func TestNormalizer_InvalidRegex(t *testing.T) {
+	assert.NotPanics(t, func() {
+		n := NewNormalizer()
+		n.SetOriginalText("name:John").
+			SetSeparator("\n").
+			SetLabels([]string{"name"}).
+			SetPatterns([]NormalizePattern{
+				{
+					Labels:       []string{"("}, // 非法正则表达式
+					MatchMethod:  RegexMatch,
+					Separator:    ":",
+					ValueKey:     "name",
+					ValueType:    "string",
+					DefaultValue: "",
+				},
+			}).
+			Parse()
+		assert.NotEqual(t, nil, n.Validate())
+		assert.Equal(t, false, n.Ok())
+	})
+
+	assert.NotPanics(t, func() {
+		n := NewNormalizer()
+		n.SetOriginalText("name:John").
+			SetSeparator("\n").
+			SetLabels([]string{"name"}).
+			SetPatterns([]NormalizePattern{
+				{
+					Labels:           []string{"name"},
+					MatchMethod:      0,
+					Separator:        "(",
+					SeparatorIsRegex: true,
+					ValueKey:         "name",
+					ValueType:        "string",
+					DefaultValue:     "",
+				},
+			}).
+			Parse()
+		assert.NotEqual(t, nil, n.Validate())
+		assert.Equal(t, false, n.Ok())
+	})
+}
+
 func Example() {
 	normalizer = NewNormalizer()
 	normalizer.SetOriginalText("name:John\\nage: 12 years\\nmy fun:Basketball,Football and Swimming").